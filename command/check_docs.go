@@ -0,0 +1,314 @@
+package command
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// CheckDocsCommand cross-validates a provider's resource/data-source
+// Markdown documentation against its schema: every argument the schema
+// exposes should be documented, and every argument the docs claim exists
+// should actually be in the schema, with a matching required/optional
+// label. It builds on the same provider-schema walker used by the schemas
+// command's canonical-JSON output.
+type CheckDocsCommand struct {
+	Meta
+}
+
+// docsIssueType enumerates the kinds of mismatch checkdocs can report.
+type docsIssueType string
+
+const (
+	docsIssueMissingFile           docsIssueType = "missing_file"
+	docsIssueUndocumentedAttribute docsIssueType = "undocumented_attribute"
+	docsIssueMissingAttribute      docsIssueType = "missing_attribute"
+	docsIssueWrongRequirement      docsIssueType = "wrong_requirement"
+	docsIssueMissingSubcategory    docsIssueType = "missing_subcategory"
+	docsIssueBadSubcategory        docsIssueType = "bad_subcategory"
+)
+
+// docsIssue is one finding in the machine-readable report.
+type docsIssue struct {
+	File   string        `json:"file,omitempty"`
+	Entry  string        `json:"entry"`
+	Name   string        `json:"name,omitempty"`
+	Type   docsIssueType `json:"type"`
+	Detail string        `json:"detail"`
+}
+
+// docsReport is the top-level JSON object checkdocs emits.
+type docsReport struct {
+	Provider string      `json:"provider"`
+	Issues   []docsIssue `json:"issues"`
+}
+
+var argReferenceHeading = regexp.MustCompile(`(?m)^##\s+Argument Reference\s*$`)
+var nextHeading = regexp.MustCompile(`(?m)^##\s+\S`)
+var argBullet = regexp.MustCompile(`(?m)^\*\s+\x60?([A-Za-z0-9_]+)\x60?\s*(?:\(([^)]*)\))?\s*-\s*(.*)$`)
+var frontMatterDelim = regexp.MustCompile(`(?s)^---\n(.*?)\n---\n`)
+var frontMatterField = regexp.MustCompile(`(?m)^([A-Za-z0-9_]+):\s*"?([^"\n]*)"?\s*$`)
+
+func (c *CheckDocsCommand) Run(args []string) int {
+	var providerName string
+	var allowedSubcategoriesRaw string
+	var requireSubcategory bool
+
+	args = c.Meta.process(args)
+
+	cmdFlags := flag.NewFlagSet("checkdocs", flag.ContinueOnError)
+	cmdFlags.StringVar(&providerName, "provider-name", "", "Name of the provider the docs describe")
+	cmdFlags.StringVar(&allowedSubcategoriesRaw, "allowed-resource-subcategories", "",
+		"Comma-separated list of valid 'subcategory' front matter values")
+	cmdFlags.BoolVar(&requireSubcategory, "require-resource-subcategory", false,
+		"Fail if a resource/data source page has no 'subcategory' front matter")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error("Cannot parse command line arguments" + err.Error())
+		cmdFlags.Usage()
+		return 1
+	}
+
+	args = cmdFlags.Args()
+	if providerName == "" || len(args) != 1 {
+		c.Ui.Error("The checkdocs command expects -provider-name and one argument with the docs directory.")
+		cmdFlags.Usage()
+		return 1
+	}
+	docsDir := args[0]
+
+	provider, found := c.getProvider(providerName)
+	if !found {
+		c.Ui.Error(fmt.Sprintf("Provider %q is not installed", providerName))
+		return 1
+	}
+
+	var allowedSubcategories map[string]bool
+	if allowedSubcategoriesRaw != "" {
+		allowedSubcategories = make(map[string]bool)
+		for _, s := range strings.Split(allowedSubcategoriesRaw, ",") {
+			allowedSubcategories[strings.TrimSpace(s)] = true
+		}
+	}
+
+	opts := docsCheckOpts{
+		providerName:         providerName,
+		docsDir:              docsDir,
+		allowedSubcategories: allowedSubcategories,
+		requireSubcategory:   requireSubcategory,
+	}
+
+	report := docsReport{Provider: providerName}
+	report.Issues = append(report.Issues, c.checkEntries(provider.ResourcesMap, "r", opts)...)
+	report.Issues = append(report.Issues, c.checkEntries(provider.DataSourcesMap, "d", opts)...)
+
+	sort.Slice(report.Issues, func(i, j int) bool {
+		if report.Issues[i].Entry != report.Issues[j].Entry {
+			return report.Issues[i].Entry < report.Issues[j].Entry
+		}
+		return report.Issues[i].Name < report.Issues[j].Name
+	})
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		c.Ui.Error("Failed to marshal docs report: " + err.Error())
+		return 1
+	}
+	c.Ui.Output(string(out))
+
+	if len(report.Issues) > 0 {
+		return 1
+	}
+	return 0
+}
+
+type docsCheckOpts struct {
+	providerName         string
+	docsDir              string
+	allowedSubcategories map[string]bool
+	requireSubcategory   bool
+}
+
+// checkEntries walks every resource or data source ("r"/"d" subdirectory)
+// a provider exposes, matches it up with its Markdown page, and diffs the
+// documented arguments against the schema.
+func (c *CheckDocsCommand) checkEntries(entries map[string]*schema.Resource, kind string, opts docsCheckOpts) []docsIssue {
+	var issues []docsIssue
+
+	for name, res := range entries {
+		block := schemaInfoToBlock(schema.InternalMap(res.Schema).Export())
+
+		path, found := findDocFile(opts.docsDir, kind, opts.providerName, name)
+		if !found {
+			issues = append(issues, docsIssue{
+				Entry:  name,
+				Type:   docsIssueMissingFile,
+				Detail: fmt.Sprintf("no documentation page found for %q under %s/%s", name, opts.docsDir, kind),
+			})
+			continue
+		}
+
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			issues = append(issues, docsIssue{
+				File:   path,
+				Entry:  name,
+				Type:   docsIssueMissingFile,
+				Detail: err.Error(),
+			})
+			continue
+		}
+		content := string(raw)
+
+		subcategory, hasSubcategory := parseFrontMatter(content)["subcategory"]
+		if opts.requireSubcategory && !hasSubcategory {
+			issues = append(issues, docsIssue{File: path, Entry: name, Type: docsIssueMissingSubcategory,
+				Detail: "page has no 'subcategory' front matter"})
+		}
+		if hasSubcategory && opts.allowedSubcategories != nil && !opts.allowedSubcategories[subcategory] {
+			issues = append(issues, docsIssue{File: path, Entry: name, Type: docsIssueBadSubcategory,
+				Detail: fmt.Sprintf("subcategory %q is not in -allowed-resource-subcategories", subcategory)})
+		}
+
+		documented := parseArgumentReference(content)
+
+		for attrName, attr := range block.Attributes {
+			// Pure Computed outputs (ids, arns, timestamps, ...) belong in
+			// "## Attributes Reference", not "## Argument Reference", which
+			// is all this command parses -- only require documentation for
+			// attributes a user can actually set.
+			if !attr.Required && !attr.Optional {
+				continue
+			}
+
+			doc, ok := documented[attrName]
+			if !ok {
+				issues = append(issues, docsIssue{File: path, Entry: name, Name: attrName,
+					Type: docsIssueUndocumentedAttribute, Detail: "attribute exists in the schema but is not documented"})
+				continue
+			}
+			if required := attr.Required; required != doc.required && (doc.required || doc.optional) {
+				issues = append(issues, docsIssue{File: path, Entry: name, Name: attrName,
+					Type:   docsIssueWrongRequirement,
+					Detail: fmt.Sprintf("schema says required=%t but docs say required=%t", required, doc.required)})
+			}
+		}
+
+		for attrName := range documented {
+			if _, ok := block.Attributes[attrName]; !ok {
+				if _, ok := block.NestedBlocks[attrName]; ok {
+					continue
+				}
+				issues = append(issues, docsIssue{File: path, Entry: name, Name: attrName,
+					Type: docsIssueMissingAttribute, Detail: "documented but not present in the schema"})
+			}
+		}
+
+		for blockName, nested := range block.NestedBlocks {
+			if !nested.required && !nested.optional {
+				continue
+			}
+			if _, ok := documented[blockName]; !ok {
+				issues = append(issues, docsIssue{File: path, Entry: name, Name: blockName,
+					Type: docsIssueUndocumentedAttribute, Detail: "nested block exists in the schema but is not documented"})
+			}
+		}
+	}
+
+	return issues
+}
+
+// findDocFile locates the Markdown page for a resource/data source, trying
+// both the provider-prefixed and bare resource name, and each of the
+// .markdown, .md and .html.markdown extensions used across the registry's
+// docs layout.
+func findDocFile(docsDir, kind, providerName, resourceName string) (string, bool) {
+	base := strings.TrimPrefix(resourceName, providerName+"_")
+	for _, name := range []string{base, resourceName} {
+		for _, ext := range []string{".markdown", ".md", ".html.markdown"} {
+			path := filepath.Join(docsDir, kind, name+ext)
+			if _, err := ioutil.ReadFile(path); err == nil {
+				return path, true
+			}
+		}
+	}
+	return "", false
+}
+
+// parseFrontMatter extracts the simple "key: value" YAML front matter
+// block terraform-provider docs use ahead of the Markdown body.
+func parseFrontMatter(content string) map[string]string {
+	result := map[string]string{}
+	m := frontMatterDelim.FindStringSubmatch(content)
+	if m == nil {
+		return result
+	}
+	for _, field := range frontMatterField.FindAllStringSubmatch(m[1], -1) {
+		result[field[1]] = strings.TrimSpace(field[2])
+	}
+	return result
+}
+
+type documentedAttr struct {
+	required bool
+	optional bool
+}
+
+// parseArgumentReference pulls the "## Argument Reference" section out of
+// a provider doc page and extracts each bulleted `name` - description
+// entry, noting whether the description calls it "Required" or "Optional".
+func parseArgumentReference(content string) map[string]documentedAttr {
+	result := map[string]documentedAttr{}
+
+	loc := argReferenceHeading.FindStringIndex(content)
+	if loc == nil {
+		return result
+	}
+	section := content[loc[1]:]
+	if end := nextHeading.FindStringIndex(section); end != nil {
+		section = section[:end[0]]
+	}
+
+	for _, m := range argBullet.FindAllStringSubmatch(section, -1) {
+		name := m[1]
+		desc := strings.ToLower(m[2] + " " + m[3])
+		result[name] = documentedAttr{
+			required: strings.Contains(desc, "required"),
+			optional: strings.Contains(desc, "optional"),
+		}
+	}
+
+	return result
+}
+
+func (c *CheckDocsCommand) Help() string {
+	helpText := `
+Usage: terraform checkdocs [options] docs-dir
+
+  Cross-validates a provider's resource/data-source Markdown documentation
+  against its schema. Reports attributes that are documented but missing
+  from the schema, attributes in the schema that are undocumented,
+  mismatched required/optional labels, and (optionally) subcategory
+  front-matter problems. Exits non-zero if any issue is found.
+
+Options:
+
+  -provider-name=name                     Name of the provider the docs describe. Required.
+
+  -allowed-resource-subcategories=list     Comma-separated list of valid 'subcategory' values.
+
+  -require-resource-subcategory            Fail if a page has no 'subcategory' front matter.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *CheckDocsCommand) Synopsis() string {
+	return "Cross-validates provider documentation against its schema"
+}
@@ -0,0 +1,47 @@
+package command
+
+import "testing"
+
+func TestParseFrontMatter(t *testing.T) {
+	content := "---\nsubcategory: \"Networking\"\npage_title: \"widget\"\n---\n\n# widget\n"
+	fm := parseFrontMatter(content)
+
+	if fm["subcategory"] != "Networking" {
+		t.Fatalf("expected subcategory 'Networking', got %q", fm["subcategory"])
+	}
+	if fm["page_title"] != "widget" {
+		t.Fatalf("expected page_title 'widget', got %q", fm["page_title"])
+	}
+}
+
+func TestParseFrontMatterMissing(t *testing.T) {
+	fm := parseFrontMatter("# widget\n\nNo front matter here.\n")
+	if len(fm) != 0 {
+		t.Fatalf("expected no front matter fields, got %v", fm)
+	}
+}
+
+func TestParseArgumentReference(t *testing.T) {
+	content := "# widget\n\n" +
+		"## Argument Reference\n\n" +
+		"The following arguments are supported:\n\n" +
+		"* `name` (Required) - The name of the widget.\n" +
+		"* `description` (Optional) - A description of the widget.\n\n" +
+		"## Attributes Reference\n\n" +
+		"* `id` - The ID of the widget.\n"
+
+	documented := parseArgumentReference(content)
+
+	if len(documented) != 2 {
+		t.Fatalf("expected 2 documented arguments, got %d: %v", len(documented), documented)
+	}
+	if !documented["name"].required {
+		t.Errorf("expected 'name' to be documented as required")
+	}
+	if !documented["description"].optional {
+		t.Errorf("expected 'description' to be documented as optional")
+	}
+	if _, ok := documented["id"]; ok {
+		t.Errorf("expected 'id' (an Attributes Reference entry) not to be picked up as an argument")
+	}
+}
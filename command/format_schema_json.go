@@ -0,0 +1,369 @@
+package command
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// schemaCacheOpts controls whether buildProviderSchemas/addProvisionerSchemas
+// consult or populate the on-disk schema cache (see schema_cache.go).
+type schemaCacheOpts struct {
+	refresh bool // -refresh-schemas: ignore cached entries, but still (re)write them
+	noCache bool // -no-schema-cache: bypass the cache entirely, reads and writes
+}
+
+// jsonSchemaFormatVersion is the format_version emitted alongside the
+// canonical JSON schema output. Bump this whenever the shape of
+// ProviderSchemas (or anything it embeds) changes in a way that a consumer
+// would need to know about.
+const jsonSchemaFormatVersion = "0.1"
+
+// ProviderSchemas is the root object produced by `terraform schemas
+// -format=terraform-json`. Its shape intentionally matches the
+// ProviderSchemas struct from github.com/hashicorp/terraform-json, so that
+// tooling built against `terraform providers schema -json` can consume this
+// fork's output without changes.
+type ProviderSchemas struct {
+	FormatVersion      string                                          `json:"format_version"`
+	ProviderSchemas    map[string]*ProviderSchema                      `json:"provider_schemas,omitempty"`
+	ProvisionerSchemas map[string]*terraform.ResourceProvisionerSchema `json:"provisioner_schemas,omitempty"`
+}
+
+// ProviderSchema is the schema for a single provider, along with the
+// resources and data sources it exposes.
+type ProviderSchema struct {
+	Provider          *Schema            `json:"provider,omitempty"`
+	ResourceSchemas   map[string]*Schema `json:"resource_schemas,omitempty"`
+	DataSourceSchemas map[string]*Schema `json:"data_source_schemas,omitempty"`
+}
+
+// Schema pairs a block's schema version with its block representation.
+type Schema struct {
+	Version uint64       `json:"version"`
+	Block   *SchemaBlock `json:"block,omitempty"`
+}
+
+// SchemaBlock is the representation of a block in a schema: the flat
+// attributes it carries directly, plus any nested blocks.
+type SchemaBlock struct {
+	Attributes   map[string]*SchemaAttribute `json:"attributes,omitempty"`
+	NestedBlocks map[string]*SchemaBlockType `json:"block_types,omitempty"`
+}
+
+// SchemaAttribute is the description of a single attribute within a block.
+type SchemaAttribute struct {
+	Type        interface{} `json:"type,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Optional    bool        `json:"optional,omitempty"`
+	Computed    bool        `json:"computed,omitempty"`
+	Sensitive   bool        `json:"sensitive,omitempty"`
+}
+
+// SchemaNestingMode describes how a nested block relates to its parent:
+// a single embedded object, or a list/set/map of them.
+type SchemaNestingMode string
+
+const (
+	SchemaNestingModeSingle SchemaNestingMode = "single"
+	SchemaNestingModeGroup  SchemaNestingMode = "group"
+	SchemaNestingModeList   SchemaNestingMode = "list"
+	SchemaNestingModeSet    SchemaNestingMode = "set"
+	SchemaNestingModeMap    SchemaNestingMode = "map"
+)
+
+// SchemaBlockType describes a nested block attached to a SchemaBlock.
+type SchemaBlockType struct {
+	NestingMode SchemaNestingMode `json:"nesting_mode,omitempty"`
+	Block       *SchemaBlock      `json:"block,omitempty"`
+	MinItems    uint64            `json:"min_items,omitempty"`
+	MaxItems    uint64            `json:"max_items,omitempty"`
+
+	// required/optional mirror the SDK-side Required/Optional flags of the
+	// attribute this nested block was exported from. They're deliberately
+	// unexported: terraform-json's own NestedBlock shape has no equivalent,
+	// and the only consumer is checkEntries (same package), which needs to
+	// tell a mandatory nested block apart from one that's genuinely optional.
+	required bool
+	optional bool
+}
+
+// schemaInfoToBlock rewrites the generic terraform.SchemaInfo produced by
+// schema.InternalMap.Export() (and schema.ExportBlock) into the canonical
+// SchemaBlock shape. SchemaInfo is a plain map[string]interface{} keyed by
+// attribute name; each value is itself a map describing that attribute
+// ("type", "description", "required", "optional", "computed", "sensitive",
+// and optionally "elem" for nested collections/blocks).
+func schemaInfoToBlock(info terraform.SchemaInfo) *SchemaBlock {
+	block := &SchemaBlock{
+		Attributes:   map[string]*SchemaAttribute{},
+		NestedBlocks: map[string]*SchemaBlockType{},
+	}
+
+	for name, raw := range info {
+		attr, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if nested, isBlock := attr["elem"].(terraform.SchemaInfo); isBlock {
+			block.NestedBlocks[name] = &SchemaBlockType{
+				NestingMode: schemaNestingMode(attr),
+				Block:       schemaInfoToBlock(nested),
+				MinItems:    toUint64(attr["min_items"]),
+				MaxItems:    toUint64(attr["max_items"]),
+				required:    toBool(attr["required"]),
+				optional:    toBool(attr["optional"]),
+			}
+			continue
+		}
+
+		block.Attributes[name] = &SchemaAttribute{
+			Type:        schemaCtyType(attr),
+			Description: toString(attr["description"]),
+			Required:    toBool(attr["required"]),
+			Optional:    toBool(attr["optional"]),
+			Computed:    toBool(attr["computed"]),
+			Sensitive:   toBool(attr["sensitive"]),
+		}
+	}
+
+	return block
+}
+
+// schemaCtyType maps the raw SDK "type" token (TypeString, TypeList, ...)
+// onto the cty-JSON type representation terraform-json actually uses:
+// primitives are a bare string ("string", "bool", "number"), collections
+// are a ["list"|"set"|"map", elementType] pair. This is what makes the
+// canonical output byte-for-byte compatible with upstream's
+// `terraform providers schema -json`, rather than just shaped like it.
+func schemaCtyType(attr map[string]interface{}) interface{} {
+	switch toString(attr["type"]) {
+	case "TypeString":
+		return "string"
+	case "TypeBool":
+		return "bool"
+	case "TypeInt", "TypeFloat":
+		return "number"
+	case "TypeList":
+		return []interface{}{"list", schemaElemCtyType(attr)}
+	case "TypeSet":
+		return []interface{}{"set", schemaElemCtyType(attr)}
+	case "TypeMap":
+		return []interface{}{"map", schemaElemCtyType(attr)}
+	default:
+		return "dynamic"
+	}
+}
+
+// schemaElemCtyType resolves the element type of a collection attribute.
+// "elem" is either absent (primitive collections default their element to
+// string, matching the SDK's own TypeMap/TypeList/TypeSet default) or a
+// nested attribute descriptor for a typed collection (e.g. TypeList of
+// TypeInt).
+func schemaElemCtyType(attr map[string]interface{}) interface{} {
+	elem, ok := attr["elem"].(map[string]interface{})
+	if !ok {
+		return "string"
+	}
+	return schemaCtyType(elem)
+}
+
+// schemaNestingMode maps the "type"/"max_items" pair the SDK exports for a
+// collection-of-blocks attribute onto the terraform-json nesting modes.
+func schemaNestingMode(attr map[string]interface{}) SchemaNestingMode {
+	// A collection of exactly one element is always a singleton nested
+	// block, regardless of which collection type the SDK modeled it with.
+	if toUint64(attr["max_items"]) == 1 {
+		return SchemaNestingModeSingle
+	}
+
+	switch toString(attr["type"]) {
+	case "TypeSet":
+		return SchemaNestingModeSet
+	case "TypeMap":
+		return SchemaNestingModeMap
+	case "TypeList":
+		return SchemaNestingModeList
+	default:
+		return SchemaNestingModeGroup
+	}
+}
+
+func toString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func toBool(v interface{}) bool {
+	b, _ := v.(bool)
+	return b
+}
+
+func toUint64(v interface{}) uint64 {
+	switch n := v.(type) {
+	case int:
+		return uint64(n)
+	case int64:
+		return uint64(n)
+	case uint64:
+		return n
+	default:
+		return 0
+	}
+}
+
+// buildProviderSchemas assembles the canonical-JSON document for one or
+// more providers, keyed by provider name under provider_schemas. names is
+// used as given; runCanonicalJson expands an empty or "*" argument into
+// every installed provider before calling this. It also returns the subset
+// of names that couldn't be resolved to an installed provider, so callers
+// can tell a typo'd/missing provider apart from a legitimately empty dump.
+func (c *SchemasCommand) buildProviderSchemas(names []string, cache schemaCacheOpts) (*ProviderSchemas, []string) {
+	result := &ProviderSchemas{
+		FormatVersion:   jsonSchemaFormatVersion,
+		ProviderSchemas: map[string]*ProviderSchema{},
+	}
+	var unresolved []string
+
+	dirs := c.pluginDirs(true)
+	sort.Strings(names)
+	for _, name := range names {
+		sha, shaOK := pluginHashFor("provider", name, dirs, cache)
+
+		if ps, ok := readProviderSchemaCache(name, sha, shaOK, cache); ok {
+			result.ProviderSchemas[name] = ps
+			continue
+		}
+
+		provider, found := c.getProvider(name)
+		if !found {
+			unresolved = append(unresolved, name)
+			continue
+		}
+
+		ps := &ProviderSchema{
+			Provider:          &Schema{Block: schemaInfoToBlock(schema.InternalMap(provider.Schema).Export())},
+			ResourceSchemas:   map[string]*Schema{},
+			DataSourceSchemas: map[string]*Schema{},
+		}
+
+		for resName, res := range provider.ResourcesMap {
+			ps.ResourceSchemas[resName] = &Schema{
+				Version: uint64(res.SchemaVersion),
+				Block:   schemaInfoToBlock(schema.InternalMap(res.Schema).Export()),
+			}
+		}
+
+		for dsName, ds := range provider.DataSourcesMap {
+			ps.DataSourceSchemas[dsName] = &Schema{
+				Version: uint64(ds.SchemaVersion),
+				Block:   schemaInfoToBlock(schema.InternalMap(ds.Schema).Export()),
+			}
+		}
+
+		result.ProviderSchemas[name] = ps
+		writeSchemaCacheIfPossible("provider", name, sha, shaOK, cache, ps)
+	}
+
+	return result, unresolved
+}
+
+// addProvisionerSchemas fills in result.ProvisionerSchemas for the given
+// provisioner names. It returns the subset of names that couldn't be
+// resolved to an installed, schema-exporting provisioner.
+func (c *SchemasCommand) addProvisionerSchemas(result *ProviderSchemas, names []string, cache schemaCacheOpts) []string {
+	dirs := c.pluginDirs(true)
+	sort.Strings(names)
+	var unresolved []string
+	for _, name := range names {
+		sha, shaOK := pluginHashFor("provisioner", name, dirs, cache)
+
+		var exported *terraform.ResourceProvisionerSchema
+		if cached, ok := readProvisionerSchemaCache(name, sha, shaOK, cache); ok {
+			exported = cached
+		} else {
+			provisioner, found := c.getProvisioner(name)
+			if !found {
+				unresolved = append(unresolved, name)
+				continue
+			}
+
+			var err error
+			exported, err = exportProvisionerSchema(provisioner)
+			if err != nil || exported == nil {
+				unresolved = append(unresolved, name)
+				continue
+			}
+			writeSchemaCacheIfPossible("provisioner", name, sha, shaOK, cache, exported)
+		}
+
+		if result.ProvisionerSchemas == nil {
+			result.ProvisionerSchemas = map[string]*terraform.ResourceProvisionerSchema{}
+		}
+		result.ProvisionerSchemas[name] = exported
+	}
+	return unresolved
+}
+
+// pluginHashFor computes the cache key (plugin binary sha256) for a
+// provider/provisioner, unless caching is disabled. A refresh still
+// computes it, since the refreshed entry is written back under that key.
+func pluginHashFor(kind, name string, dirs []string, cache schemaCacheOpts) (string, bool) {
+	if cache.noCache {
+		return "", false
+	}
+	path, found := findPluginBinary(kind, name, dirs)
+	if !found {
+		return "", false
+	}
+	sha, err := pluginSHA256(path)
+	if err != nil {
+		return "", false
+	}
+	return sha, true
+}
+
+func readProviderSchemaCache(name, sha string, shaOK bool, cache schemaCacheOpts) (*ProviderSchema, bool) {
+	if !shaOK || cache.noCache || cache.refresh {
+		return nil, false
+	}
+	raw, ok := readSchemaCache("provider", name, sha)
+	if !ok {
+		return nil, false
+	}
+	var ps ProviderSchema
+	if err := json.Unmarshal(raw, &ps); err != nil {
+		return nil, false
+	}
+	return &ps, true
+}
+
+func readProvisionerSchemaCache(name, sha string, shaOK bool, cache schemaCacheOpts) (*terraform.ResourceProvisionerSchema, bool) {
+	if !shaOK || cache.noCache || cache.refresh {
+		return nil, false
+	}
+	raw, ok := readSchemaCache("provisioner", name, sha)
+	if !ok {
+		return nil, false
+	}
+	var schema terraform.ResourceProvisionerSchema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, false
+	}
+	return &schema, true
+}
+
+func writeSchemaCacheIfPossible(kind, name, sha string, shaOK bool, cache schemaCacheOpts, v interface{}) {
+	if !shaOK || cache.noCache {
+		return
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	_ = writeSchemaCache(kind, name, sha, raw)
+}
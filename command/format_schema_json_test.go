@@ -0,0 +1,125 @@
+package command
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestSchemaNestingMode(t *testing.T) {
+	cases := []struct {
+		name string
+		attr map[string]interface{}
+		want SchemaNestingMode
+	}{
+		{"list", map[string]interface{}{"type": "TypeList"}, SchemaNestingModeList},
+		{"list singleton", map[string]interface{}{"type": "TypeList", "max_items": 1}, SchemaNestingModeSingle},
+		{"set", map[string]interface{}{"type": "TypeSet"}, SchemaNestingModeSet},
+		{"set singleton", map[string]interface{}{"type": "TypeSet", "max_items": 1}, SchemaNestingModeSingle},
+		{"map", map[string]interface{}{"type": "TypeMap"}, SchemaNestingModeMap},
+		{"unknown", map[string]interface{}{"type": "TypeBool"}, SchemaNestingModeGroup},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := schemaNestingMode(tc.attr); got != tc.want {
+				t.Fatalf("schemaNestingMode(%v) = %q, want %q", tc.attr, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSchemaCtyType(t *testing.T) {
+	cases := []struct {
+		name string
+		attr map[string]interface{}
+		want interface{}
+	}{
+		{"string", map[string]interface{}{"type": "TypeString"}, "string"},
+		{"bool", map[string]interface{}{"type": "TypeBool"}, "bool"},
+		{"int", map[string]interface{}{"type": "TypeInt"}, "number"},
+		{"float", map[string]interface{}{"type": "TypeFloat"}, "number"},
+		{
+			"list of string",
+			map[string]interface{}{"type": "TypeList"},
+			[]interface{}{"list", "string"},
+		},
+		{
+			"set of string",
+			map[string]interface{}{"type": "TypeSet"},
+			[]interface{}{"set", "string"},
+		},
+		{
+			"map of string",
+			map[string]interface{}{"type": "TypeMap"},
+			[]interface{}{"map", "string"},
+		},
+		{
+			"list of int",
+			map[string]interface{}{"type": "TypeList", "elem": map[string]interface{}{"type": "TypeInt"}},
+			[]interface{}{"list", "number"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := schemaCtyType(tc.attr)
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(tc.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Fatalf("schemaCtyType(%v) = %s, want %s", tc.attr, gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestSchemaInfoToBlock(t *testing.T) {
+	info := terraform.SchemaInfo{
+		"name": map[string]interface{}{
+			"type":     "TypeString",
+			"required": true,
+		},
+		"id": map[string]interface{}{
+			"type":     "TypeString",
+			"computed": true,
+		},
+		"tags": map[string]interface{}{
+			"type":      "TypeSet",
+			"optional":  true,
+			"max_items": 1,
+			"elem": terraform.SchemaInfo{
+				"key": map[string]interface{}{
+					"type":     "TypeString",
+					"required": true,
+				},
+			},
+		},
+	}
+
+	block := schemaInfoToBlock(info)
+
+	if len(block.Attributes) != 2 {
+		t.Fatalf("expected 2 flat attributes, got %d: %v", len(block.Attributes), block.Attributes)
+	}
+	if !block.Attributes["name"].Required {
+		t.Errorf("expected 'name' to be required")
+	}
+	if block.Attributes["name"].Type != "string" {
+		t.Errorf("expected 'name' type to be cty-JSON %q, got %v", "string", block.Attributes["name"].Type)
+	}
+	if !block.Attributes["id"].Computed {
+		t.Errorf("expected 'id' to be computed")
+	}
+
+	nested, ok := block.NestedBlocks["tags"]
+	if !ok {
+		t.Fatalf("expected 'tags' to be a nested block")
+	}
+	if nested.NestingMode != SchemaNestingModeSingle {
+		t.Errorf("expected 'tags' nesting mode to be %q, got %q", SchemaNestingModeSingle, nested.NestingMode)
+	}
+	if _, ok := nested.Block.Attributes["key"]; !ok {
+		t.Errorf("expected nested block to carry 'key' attribute")
+	}
+}
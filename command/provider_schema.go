@@ -0,0 +1,48 @@
+package command
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// getProvider resolves an installed provider plugin by name and returns its
+// underlying *schema.Provider so its ResourcesMap/DataSourcesMap/Schema can
+// be introspected. Providers (unlike backends) are plugins rather than a
+// compile-time registry, so this goes through the same provider factories
+// Meta builds for a normal terraform.Context. It lives on Meta (rather than
+// on SchemasCommand) so any command that embeds Meta -- schemas, checkdocs,
+// and whatever comes after -- can share it.
+func (m *Meta) getProvider(name string) (*schema.Provider, bool) {
+	factories, err := m.providerFactories()
+	if err != nil {
+		return nil, false
+	}
+
+	factory, ok := factories[name]
+	if !ok {
+		return nil, false
+	}
+
+	raw, err := factory()
+	if err != nil {
+		return nil, false
+	}
+
+	provider, ok := raw.(*schema.Provider)
+	return provider, ok
+}
+
+// getProviderNames returns the names of every provider Meta can resolve,
+// used to back "*"/omitted-name dumps in the canonical-JSON output mode of
+// the schemas command.
+func (m *Meta) getProviderNames() []string {
+	factories, err := m.providerFactories()
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	return names
+}
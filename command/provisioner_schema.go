@@ -0,0 +1,58 @@
+package command
+
+import "github.com/hashicorp/terraform/terraform"
+
+// provisionerExporter is implemented by any ResourceProvisioner that can
+// describe its own schema, the same way MockResourceProvisioner does for
+// tests. Asserting against this instead of a method on the
+// ResourceProvisioner interface lets provisioners pick up export support
+// independently of everything else that interface carries.
+type provisionerExporter interface {
+	Export() (*terraform.ResourceProvisionerSchema, error)
+}
+
+// getProvisioner resolves an installed provisioner plugin by name, the
+// provisioner equivalent of Meta.getProvider.
+func (m *Meta) getProvisioner(name string) (terraform.ResourceProvisioner, bool) {
+	factories, err := m.provisionerFactories()
+	if err != nil {
+		return nil, false
+	}
+
+	factory, ok := factories[name]
+	if !ok {
+		return nil, false
+	}
+
+	raw, err := factory()
+	if err != nil {
+		return nil, false
+	}
+
+	return raw, true
+}
+
+// getProvisionerNames returns the names of every provisioner Meta can
+// resolve, used to back "*"/omitted-name dumps.
+func (m *Meta) getProvisionerNames() []string {
+	factories, err := m.provisionerFactories()
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	return names
+}
+
+// exportProvisionerSchema fetches a provisioner's schema if it implements
+// provisionerExporter, returning (nil, nil) for provisioners that don't.
+func exportProvisionerSchema(p terraform.ResourceProvisioner) (*terraform.ResourceProvisionerSchema, error) {
+	exporter, ok := p.(provisionerExporter)
+	if !ok {
+		return nil, nil
+	}
+	return exporter.Export()
+}
@@ -0,0 +1,68 @@
+package command
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// nonExportingProvisioner implements terraform.ResourceProvisioner without
+// the optional Export() method, so it fails the provisionerExporter type
+// assertion -- the provisioner-plugin equivalent of a provider that hasn't
+// picked up schema support yet.
+type nonExportingProvisioner struct{}
+
+func (p *nonExportingProvisioner) Validate(c *terraform.ResourceConfig) ([]string, []error) {
+	return nil, nil
+}
+
+func (p *nonExportingProvisioner) Apply(o terraform.UIOutput, s *terraform.InstanceState, c *terraform.ResourceConfig) error {
+	return nil
+}
+
+func (p *nonExportingProvisioner) Stop() error {
+	return nil
+}
+
+func TestExportProvisionerSchema_implements(t *testing.T) {
+	want := &terraform.ResourceProvisionerSchema{}
+	mock := &terraform.MockResourceProvisioner{
+		ExportReturn: want,
+	}
+
+	got, err := exportProvisionerSchema(mock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != want {
+		t.Fatalf("expected exportProvisionerSchema to return the mock's ExportReturn")
+	}
+	if !mock.ExportCalled {
+		t.Fatalf("expected Export() to have been called on the mock")
+	}
+}
+
+func TestExportProvisionerSchema_implementsError(t *testing.T) {
+	mock := &terraform.MockResourceProvisioner{
+		ExportReturnError: errors.New("boom"),
+	}
+
+	got, err := exportProvisionerSchema(mock)
+	if err == nil {
+		t.Fatalf("expected the mock's ExportReturnError to be propagated")
+	}
+	if got != nil {
+		t.Fatalf("expected a nil schema alongside the error, got %v", got)
+	}
+}
+
+func TestExportProvisionerSchema_doesNotImplement(t *testing.T) {
+	got, err := exportProvisionerSchema(&nonExportingProvisioner{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("expected a nil schema for a provisioner without Export(), got %v", got)
+	}
+}
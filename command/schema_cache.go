@@ -0,0 +1,113 @@
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform/plugin"
+	"github.com/hashicorp/terraform/plugin/discovery"
+)
+
+// schemaCacheSubdir is appended to $TF_PLUGIN_CACHE_DIR (or ".terraform" as
+// a fallback) to get the directory cached schema dumps live under.
+const schemaCacheSubdir = "schemas"
+
+// schemaCacheEntry is what's persisted on disk for one provider or
+// provisioner's canonical-JSON schema. FormatVersion and PluginSHA256 are
+// both checked on read: either one changing (a serializer bump, or the
+// plugin binary being rebuilt/upgraded) invalidates the entry.
+type schemaCacheEntry struct {
+	FormatVersion   string          `json:"format_version"`
+	PluginSHA256    string          `json:"plugin_sha256"`
+	ProtocolVersion int             `json:"protocol_version"`
+	Schema          json.RawMessage `json:"schema"`
+}
+
+// schemaCacheDir returns the directory schema cache entries are read from
+// and written to.
+func schemaCacheDir() string {
+	if dir := os.Getenv("TF_PLUGIN_CACHE_DIR"); dir != "" {
+		return filepath.Join(dir, schemaCacheSubdir)
+	}
+	return filepath.Join(".terraform", schemaCacheSubdir)
+}
+
+// pluginSHA256 hashes a plugin binary on disk, used as (half of) the cache
+// key so a rebuilt/upgraded plugin invalidates its stale schema entry.
+func pluginSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// findPluginBinary locates the on-disk binary for an installed provider or
+// provisioner plugin of the given discovery kind ("provider"/"provisioner"),
+// so its contents can be hashed for cache invalidation.
+func findPluginBinary(kind, name string, dirs []string) (string, bool) {
+	metas := discovery.FindPlugins(kind, dirs).WithName(name)
+	newest := metas.Newest()
+	if newest == nil {
+		return "", false
+	}
+	return newest.Path, true
+}
+
+func schemaCacheFile(kind, name, sha string) string {
+	return filepath.Join(schemaCacheDir(), fmt.Sprintf("%s-%s-%s.json", kind, name, sha))
+}
+
+// readSchemaCache returns the cached canonical-JSON schema for a plugin, if
+// a valid entry exists: its plugin hash, format_version, and
+// protocol_version must all still match, so a binary rebuild, a serializer
+// change, or a plugin protocol bump each invalidate it independently.
+func readSchemaCache(kind, name, sha string) (json.RawMessage, bool) {
+	raw, err := ioutil.ReadFile(schemaCacheFile(kind, name, sha))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry schemaCacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, false
+	}
+	if entry.FormatVersion != jsonSchemaFormatVersion || entry.PluginSHA256 != sha {
+		return nil, false
+	}
+	if entry.ProtocolVersion != plugin.Handshake.ProtocolVersion {
+		return nil, false
+	}
+	return entry.Schema, true
+}
+
+// writeSchemaCache persists a plugin's canonical-JSON schema to disk.
+func writeSchemaCache(kind, name, sha string, schemaJSON json.RawMessage) error {
+	if err := os.MkdirAll(schemaCacheDir(), 0755); err != nil {
+		return err
+	}
+
+	entry := schemaCacheEntry{
+		FormatVersion:   jsonSchemaFormatVersion,
+		PluginSHA256:    sha,
+		ProtocolVersion: plugin.Handshake.ProtocolVersion,
+		Schema:          schemaJSON,
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(schemaCacheFile(kind, name, sha), raw, 0644)
+}
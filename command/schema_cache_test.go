@@ -0,0 +1,77 @@
+package command
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/plugin"
+)
+
+func withTempPluginCacheDir(t *testing.T) func() {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "schema-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old, hadOld := os.LookupEnv("TF_PLUGIN_CACHE_DIR")
+	os.Setenv("TF_PLUGIN_CACHE_DIR", dir)
+
+	return func() {
+		os.RemoveAll(dir)
+		if hadOld {
+			os.Setenv("TF_PLUGIN_CACHE_DIR", old)
+		} else {
+			os.Unsetenv("TF_PLUGIN_CACHE_DIR")
+		}
+	}
+}
+
+func TestSchemaCacheRoundTrip(t *testing.T) {
+	defer withTempPluginCacheDir(t)()
+
+	payload := json.RawMessage(`{"hello":"world"}`)
+	if err := writeSchemaCache("provider", "widget", "deadbeef", payload); err != nil {
+		t.Fatalf("writeSchemaCache: %v", err)
+	}
+
+	got, ok := readSchemaCache("provider", "widget", "deadbeef")
+	if !ok {
+		t.Fatalf("expected a cache hit after writing")
+	}
+	if string(got) != string(payload) {
+		t.Fatalf("got %s, want %s", got, payload)
+	}
+
+	if _, ok := readSchemaCache("provider", "widget", "otherhash"); ok {
+		t.Fatalf("expected a cache miss for a different plugin hash")
+	}
+}
+
+func TestSchemaCacheInvalidatesOnProtocolVersionChange(t *testing.T) {
+	defer withTempPluginCacheDir(t)()
+
+	entry := schemaCacheEntry{
+		FormatVersion:   jsonSchemaFormatVersion,
+		PluginSHA256:    "deadbeef",
+		ProtocolVersion: plugin.Handshake.ProtocolVersion + 1,
+		Schema:          json.RawMessage(`{}`),
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(schemaCacheDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(schemaCacheFile("provider", "widget", "deadbeef"), raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := readSchemaCache("provider", "widget", "deadbeef"); ok {
+		t.Fatalf("expected a stale protocol_version to invalidate the cache entry")
+	}
+}
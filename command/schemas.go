@@ -1,12 +1,15 @@
 package command
 
 import (
+	"encoding/json"
 	"flag"
+	"fmt"
 	"github.com/hashicorp/hil"
 	"github.com/hashicorp/hil/ast"
 	"github.com/hashicorp/terraform/backend/init"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
+	"sort"
 	"strings"
 )
 
@@ -49,16 +52,39 @@ type backendSchema struct {
 	Schema terraform.SchemaInfo `json:"schema"`
 }
 
+type providerSchema struct {
+	resultBase
+	Provider    terraform.SchemaInfo            `json:"provider,omitempty"`
+	Resources   map[string]terraform.SchemaInfo `json:"resources,omitempty"`
+	DataSources map[string]terraform.SchemaInfo `json:"data_sources,omitempty"`
+}
+
+type provisionerSchema struct {
+	resultBase
+	Schema *terraform.ResourceProvisionerSchema `json:"schema"`
+}
+
 func (c *SchemasCommand) Run(args []string) int {
 	var indent bool
 	var inJson bool
 	var inXml bool
+	var format string
+	var providersSchema bool
+	var refreshSchemas bool
+	var noSchemaCache bool
 
 	args = c.Meta.process(args)
 
 	cmdFlags := flag.NewFlagSet("schemas", flag.ContinueOnError)
 	cmdFlags.BoolVar(&indent, "indent", false, "Indent output")
 	cmdFlags.BoolVar(&inJson, "json", false, "In JSON format")
+	cmdFlags.StringVar(&format, "format", "", "Output format. Currently only 'terraform-json' is supported, "+
+		"which dumps the combined provider/resource/data-source schemas in the canonical terraform-json format.")
+	cmdFlags.BoolVar(&providersSchema, "providers-schema", false, "Shorthand for -format=terraform-json")
+	cmdFlags.BoolVar(&refreshSchemas, "refresh-schemas", false,
+		"With -format=terraform-json, ignore the on-disk schema cache and re-launch every plugin")
+	cmdFlags.BoolVar(&noSchemaCache, "no-schema-cache", false,
+		"With -format=terraform-json, bypass the on-disk schema cache entirely (no reads, no writes)")
 	// Temporarily disabled due to not-implemented xml serializer for SchemaInfo (which is map[string]interface{})
 	//cmdFlags.BoolVar(&inXml, "xml", false, "In XML format")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
@@ -68,6 +94,10 @@ func (c *SchemasCommand) Run(args []string) int {
 		return 1
 	}
 
+	if providersSchema {
+		format = "terraform-json"
+	}
+
 	if inXml && inJson {
 		c.Ui.Error("Cannot produce output in both xml in json formats at the same time. Either use -json or -xml flags")
 		return 1
@@ -76,7 +106,13 @@ func (c *SchemasCommand) Run(args []string) int {
 	if inXml || inJson {
 		c.color = false
 	}
-	var format string
+
+	args = cmdFlags.Args()
+
+	if format == "terraform-json" {
+		return c.runCanonicalJson(args, indent, schemaCacheOpts{refresh: refreshSchemas, noCache: noSchemaCache})
+	}
+
 	if inJson {
 		format = "json"
 	} else if inXml {
@@ -85,7 +121,6 @@ func (c *SchemasCommand) Run(args []string) int {
 		format = "plain"
 	}
 
-	args = cmdFlags.Args()
 	if len(args) != 1 {
 		c.Ui.Error("The schemas command expects one argument with the type of provider/resource.")
 		cmdFlags.Usage()
@@ -93,7 +128,7 @@ func (c *SchemasCommand) Run(args []string) int {
 	}
 
 	var s interface{}
-	s = getAnythingOrErrorResult(args[0])
+	s = c.getAnythingOrErrorResult(args[0])
 
 	c.Ui.Output(FormatSchema(&FormatSchemaOpts{
 		Name:      args[0],
@@ -113,6 +148,63 @@ func (c *SchemasCommand) Run(args []string) int {
 	}
 }
 
+// runCanonicalJson handles `-format=terraform-json` / `-providers-schema`:
+// it dumps every installed provider (or just the ones named in args) in the
+// same shape as `terraform providers schema -json`, so downstream tooling
+// written against upstream's output works unmodified against this fork.
+func (c *SchemasCommand) runCanonicalJson(names []string, indent bool, cache schemaCacheOpts) int {
+	all := len(names) == 0 || (len(names) == 1 && names[0] == "*")
+
+	providerNames := names
+	provisionerNames := names
+	if all {
+		providerNames = c.getProviderNames()
+		provisionerNames = c.getProvisionerNames()
+	}
+
+	schemas, unresolvedProviders := c.buildProviderSchemas(providerNames, cache)
+	unresolvedProvisioners := c.addProvisionerSchemas(schemas, provisionerNames, cache)
+
+	var unresolved []string
+	if !all {
+		// An explicitly named provider/provisioner only counts as truly
+		// unresolved if it matched neither -- e.g. a provisioner name is
+		// expected to come back unresolved from buildProviderSchemas.
+		unresolvedSet := map[string]bool{}
+		for _, name := range unresolvedProviders {
+			unresolvedSet[name] = true
+		}
+		for _, name := range unresolvedProvisioners {
+			if unresolvedSet[name] {
+				unresolved = append(unresolved, name)
+			}
+		}
+	}
+
+	var out []byte
+	var err error
+	if indent {
+		out, err = json.MarshalIndent(schemas, "", "  ")
+	} else {
+		out, err = json.Marshal(schemas)
+	}
+	if err != nil {
+		c.Ui.Error("Failed to marshal provider schemas: " + err.Error())
+		return 1
+	}
+
+	c.Ui.Output(string(out))
+
+	if len(unresolved) > 0 {
+		sort.Strings(unresolved)
+		c.Ui.Error(fmt.Sprintf("The following names did not resolve to an installed provider or provisioner: %s",
+			strings.Join(unresolved, ", ")))
+		return 1
+	}
+
+	return 0
+}
+
 func (c *SchemasCommand) Help() string {
 	helpText := `
 Usage: terraform schemas [options] name
@@ -125,6 +217,27 @@ Options:
   -indent		      If specified, output would be indented.
 
   -json		          If specified, output would be in JSON format. Implies '--no-color'.
+
+  -format=terraform-json  Dump the combined provider/resource/data-source schemas in the
+                      canonical format produced by 'terraform providers schema -json'.
+                      'name' may be '*' or omitted to dump every installed provider.
+
+  -providers-schema  Shorthand for -format=terraform-json.
+
+  -refresh-schemas    With -format=terraform-json, ignore the on-disk schema
+                      cache and re-launch every plugin.
+
+  -no-schema-cache    With -format=terraform-json, bypass the on-disk schema
+                      cache entirely (neither read from nor write to it).
+
+  With -format=terraform-json, schemas are cached under
+  $TF_PLUGIN_CACHE_DIR/schemas (or .terraform/schemas if that's unset), keyed
+  by the plugin binary's sha256 and invalidated automatically whenever the
+  serializer's format_version changes.
+
+  Provisioners (e.g. 'file', 'remote-exec', 'chef') are introspectable the
+  same way: 'terraform schemas file' works, and provisioners are included
+  alongside providers in '-format=terraform-json' dumps.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -133,7 +246,7 @@ func (c *SchemasCommand) Synopsis() string {
 	return "Shows schemas of Terraform providers/resources"
 }
 
-func getAnythingOrErrorResult(name string) interface{} {
+func (c *SchemasCommand) getAnythingOrErrorResult(name string) interface{} {
 	if name == "functions" {
 		return functionsSchema{resultBase{"functions", "functions"}, getInterpolationFunctions()}
 	}
@@ -145,6 +258,14 @@ func getAnythingOrErrorResult(name string) interface{} {
 	if s != nil {
 		return s
 	}
+	s = c.getProviderSchema(name)
+	if s != nil {
+		return s
+	}
+	s = c.getProvisionerSchema(name)
+	if s != nil {
+		return s
+	}
 	return errorResult{resultBase{name, "unknown"}, "Not found"}
 }
 
@@ -226,3 +347,44 @@ func getBackendSchema(name string) interface{} {
 	}
 	return backendSchema{resultBase{name, "backend"}, *backend}
 }
+
+func (c *SchemasCommand) getProviderSchema(name string) interface{} {
+	provider, found := c.getProvider(name)
+	if !found {
+		return nil
+	}
+
+	resources := make(map[string]terraform.SchemaInfo, len(provider.ResourcesMap))
+	for resName, res := range provider.ResourcesMap {
+		resources[resName] = schema.InternalMap(res.Schema).Export()
+	}
+
+	dataSources := make(map[string]terraform.SchemaInfo, len(provider.DataSourcesMap))
+	for dsName, ds := range provider.DataSourcesMap {
+		dataSources[dsName] = schema.InternalMap(ds.Schema).Export()
+	}
+
+	return providerSchema{
+		resultBase:  resultBase{name, "provider"},
+		Provider:    schema.InternalMap(provider.Schema).Export(),
+		Resources:   resources,
+		DataSources: dataSources,
+	}
+}
+
+func (c *SchemasCommand) getProvisionerSchema(name string) interface{} {
+	provisioner, found := c.getProvisioner(name)
+	if !found {
+		return nil
+	}
+
+	exported, err := exportProvisionerSchema(provisioner)
+	if err != nil {
+		return errorResult{resultBase{name, "provisioner"}, err.Error()}
+	}
+	if exported == nil {
+		return nil
+	}
+
+	return provisionerSchema{resultBase{name, "provisioner"}, exported}
+}